@@ -0,0 +1,115 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+)
+
+const (
+	keyTemplateEnv = "LP4K_S3_KEY_TEMPLATE"
+	clusterNameEnv = "LP4K_CLUSTER_NAME"
+
+	defaultKeyTemplate = "{{.Prefix}}/cluster={{.Cluster}}/year={{.Year}}/month={{.Month}}/day={{.Day}}/nodeclaims-{{.Timestamp}}.{{.Ext}}"
+
+	// defaultDedupeKeyTemplate is used instead of defaultKeyTemplate when
+	// LP4K_S3_DEDUPE is set and the user hasn't supplied their own
+	// LP4K_S3_KEY_TEMPLATE: a Timestamp-keyed default would make every run
+	// produce a new key, so HeadObject would never find a match.
+	defaultDedupeKeyTemplate = "{{.Prefix}}/cluster={{.Cluster}}/year={{.Year}}/month={{.Month}}/day={{.Day}}/nodeclaims-{{.Hash}}.{{.Ext}}"
+)
+
+// KeyTemplateData is the set of fields available to LP4K_S3_KEY_TEMPLATE,
+// chosen to let users Hive-partition output (cluster/year/month/day/hour)
+// for Athena/Glue crawling.
+type KeyTemplateData struct {
+	Prefix    string
+	Cluster   string
+	Year      string
+	Month     string
+	Day       string
+	Hour      string
+	Timestamp string
+	Ext       string
+
+	// Hash is the hex-encoded SHA-256 of the serialized payload. Templates
+	// that reference it instead of Timestamp get a deterministic,
+	// content-addressed key, which is what makes LP4K_S3_DEDUPE useful.
+	Hash string
+}
+
+// buildKey renders LP4K_S3_KEY_TEMPLATE (or a default template) into an
+// object key for this upload. With dedupe set and no explicit template,
+// the default keys on content hash rather than timestamp.
+func buildKey(ext, hash string, dedupe bool) (string, error) {
+	tmplSrc := os.Getenv(keyTemplateEnv)
+	if tmplSrc == "" {
+		if dedupe {
+			tmplSrc = defaultDedupeKeyTemplate
+		} else {
+			tmplSrc = defaultKeyTemplate
+		}
+	}
+
+	tmpl, err := template.New("key").Parse(tmplSrc)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", keyTemplateEnv, err)
+	}
+
+	now := time.Now()
+	data := KeyTemplateData{
+		Prefix:    strings.TrimSuffix(s3Prefix, "/"),
+		Cluster:   clusterName(),
+		Year:      now.Format("2006"),
+		Month:     now.Format("01"),
+		Day:       now.Format("02"),
+		Hour:      now.Format("15"),
+		Timestamp: now.Format("2006-01-02-15-04-05"),
+		Ext:       ext,
+		Hash:      hash,
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", keyTemplateEnv, err)
+	}
+
+	return buf.String(), nil
+}
+
+// clusterName resolves LP4K_CLUSTER_NAME, falling back to the instance's
+// "eks:cluster-name" tag via IMDS when it's unset. Any IMDS failure (not
+// running on EC2, tags not exposed to instances, etc.) just yields an
+// empty Cluster rather than failing the upload.
+func clusterName() string {
+	if name := os.Getenv(clusterNameEnv); name != "" {
+		return name
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := imds.New(imds.Options{}).GetMetadata(ctx, &imds.GetMetadataInput{
+		Path: "tags/instance/eks:cluster-name",
+	})
+	if err != nil {
+		return ""
+	}
+	defer resp.Content.Close()
+
+	b, err := io.ReadAll(resp.Content)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(b))
+}
@@ -4,25 +4,30 @@ package s3
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
-	"reflect"
-	"strings"
-	"time"
-
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 
 	lp4k "github.com/awslabs/LogParserForKarpenter/parser"
+	"github.com/awslabs/LogParserForKarpenter/serializer"
+	"github.com/awslabs/LogParserForKarpenter/uploader"
 )
 
 const (
 	// environment variables
-	s3BucketEnv = "LP4K_S3_BUCKET"
-	s3PrefixEnv = "LP4K_S3_PREFIX"
-	s3RegionEnv = "LP4K_S3_REGION"
+	s3BucketEnv     = "LP4K_S3_BUCKET"
+	s3PrefixEnv     = "LP4K_S3_PREFIX"
+	s3RegionEnv     = "LP4K_S3_REGION"
+	uploaderEnv     = "LP4K_UPLOADER"
+	compressEnv     = "LP4K_COMPRESS"
+	dedupeEnv       = "LP4K_S3_DEDUPE"
+	gzipCompression = "gzip"
 )
 
 var s3Bucket, s3Prefix, s3Region string
@@ -34,8 +39,10 @@ func init() {
 	s3Prefix = os.Getenv(s3PrefixEnv)
 	s3Region = os.Getenv(s3RegionEnv)
 
-	// S3 is enabled only if bucket is specified
-	s3Enabled = s3Bucket != ""
+	// Uploading is enabled if a bucket is configured (the historical S3
+	// trigger) or the user has explicitly picked a backend, since the
+	// file and webdav backends don't need a bucket at all.
+	s3Enabled = s3Bucket != "" || os.Getenv(uploaderEnv) != ""
 
 	if s3Enabled {
 		if s3Prefix == "" {
@@ -44,7 +51,7 @@ func init() {
 		if s3Region == "" {
 			s3Region = "us-east-1"
 		}
-		fmt.Fprintf(os.Stderr, "S3 upload enabled: bucket=%s, prefix=%s, region=%s\n", s3Bucket, s3Prefix, s3Region)
+		fmt.Fprintf(os.Stderr, "Upload enabled: bucket=%s, prefix=%s, region=%s\n", s3Bucket, s3Prefix, s3Region)
 	}
 }
 
@@ -58,95 +65,130 @@ func GetConfig() (bucket, prefix, region string) {
 	return s3Bucket, s3Prefix, s3Region
 }
 
-// helper function to convert nodeclaimmap to CSV string with header
-func convertToCSV(nodeclaimmap *map[string]lp4k.Nodeclaimstruct) string {
-	var csvBuffer bytes.Buffer
-	var header string
-	var nodeclaimstruct lp4k.Nodeclaimstruct
-
-	// Generate header using reflect
-	reflecttype := reflect.TypeOf(nodeclaimstruct)
-	header = "Nodeclaim[1]"
-	for i := range reflecttype.NumField() {
-		header = fmt.Sprintf("%s,%s[%d]", header, reflecttype.Field(i).Name, i+2)
+// UploadToS3 serializes the nodeclaim data in the configured output format
+// (LP4K_OUTPUT_FORMAT; CSV by default) and uploads it through the
+// configured uploader backend (S3-compatible by default; see the uploader
+// package for the full set of backends and LP4K_UPLOADER).
+func UploadToS3(ctx context.Context, nodeclaimmap *map[string]lp4k.Nodeclaimstruct) error {
+	if !s3Enabled {
+		return nil
+	}
+
+	ser, err := serializer.New()
+	if err != nil {
+		return err
 	}
 
-	// Write header
-	csvBuffer.WriteString(header)
-	csvBuffer.WriteString("\n")
+	u, err := uploader.New()
+	if err != nil {
+		return err
+	}
 
-	if len(*nodeclaimmap) == 0 {
-		return csvBuffer.String()
+	data, err := ser.Serialize(nodeclaimmap)
+	if err != nil {
+		return fmt.Errorf("failed to serialize nodeclaims: %w", err)
 	}
 
-	// Sort and write data
-	type keyvalue struct {
-		key   string
-		value lp4k.Nodeclaimstruct
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	compress := os.Getenv(compressEnv) == gzipCompression
+	dedupe := os.Getenv(dedupeEnv) == "true"
+
+	ext := ser.Ext()
+	if compress {
+		ext += ".gz"
 	}
 
-	s := make([]keyvalue, 0, len(*nodeclaimmap))
-	for k, v := range *nodeclaimmap {
-		s = append(s, keyvalue{k, v})
+	key, err := buildKey(ext, hash, dedupe)
+	if err != nil {
+		return err
 	}
 
-	// Sort by created time (simple bubble sort for consistency)
-	for i := 0; i < len(s); i++ {
-		for j := i + 1; j < len(s); j++ {
-			if s[i].value.Createdtime > s[j].value.Createdtime {
-				s[i], s[j] = s[j], s[i]
+	if dedupe {
+		if exister, ok := u.(uploader.Exister); ok {
+			exists, err := exister.Exists(ctx, key)
+			if err != nil {
+				return fmt.Errorf("failed to check for existing object %s: %w", key, err)
+			}
+			if exists {
+				fmt.Fprintf(os.Stderr, "Skipping upload: %s already exists\n", key)
+				return nil
 			}
 		}
 	}
 
-	// Write each nodeclaim row
-	for _, v := range s {
-		csvBuffer.WriteString(v.key)
+	var body io.Reader
+	var opts uploader.Options
+
+	if dedupe {
+		// Dedupe mode needs Content-MD5 of the exact bytes being sent, so it
+		// buffers the (optionally compressed) payload in memory rather than
+		// streaming it, trading the streaming guarantee for an integrity
+		// check and a HeadObject-able key. Non-dedupe uploads stream through
+		// bodyFor, so no second, compressed copy of the payload is held.
+		payload, err := maybeCompress(data, compress)
+		if err != nil {
+			return fmt.Errorf("failed to compress nodeclaims: %w", err)
+		}
 
-		reflectval := reflect.ValueOf(v.value)
-		for i := range reflectval.NumField() {
-			csvBuffer.WriteString(fmt.Sprintf(",%v", reflectval.Field(i).Interface()))
+		md5sum := md5.Sum(payload)
+		body = bytes.NewReader(payload)
+		opts = uploader.Options{
+			ContentMD5:    base64.StdEncoding.EncodeToString(md5sum[:]),
+			ContentSHA256: hash,
 		}
-		csvBuffer.WriteString("\n")
+		if compress {
+			opts.ContentEncoding = gzipCompression
+		}
+	} else {
+		body, opts = bodyFor(data, compress)
 	}
 
-	return csvBuffer.String()
-}
-
-// UploadToS3 uploads the nodeclaim CSV data to S3
-func UploadToS3(ctx context.Context, nodeclaimmap *map[string]lp4k.Nodeclaimstruct) error {
-	if !s3Enabled {
-		return nil
+	if err := u.Upload(ctx, key, body, ser.ContentType(), opts); err != nil {
+		return fmt.Errorf("failed to upload: %w", err)
 	}
 
-	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(s3Region))
-	if err != nil {
-		return fmt.Errorf("unable to load AWS SDK config: %w", err)
-	}
+	fmt.Fprintf(os.Stderr, "Successfully uploaded to %s\n", key)
+	return nil
+}
 
-	// Create S3 client
-	client := s3.NewFromConfig(cfg)
+// bodyFor wraps data in a gzip.Writer piped straight to the uploader when
+// compress is set, so the uploader streams compressed bytes instead of
+// buffering a second, fully-compressed copy in memory.
+func bodyFor(data []byte, compress bool) (io.Reader, uploader.Options) {
+	if !compress {
+		return bytes.NewReader(data), uploader.Options{}
+	}
 
-	// Convert nodeclaimmap to CSV
-	csvData := convertToCSV(nodeclaimmap)
+	pr, pw := io.Pipe()
+	go func() {
+		gz := gzip.NewWriter(pw)
+		_, err := gz.Write(data)
+		if err == nil {
+			err = gz.Close()
+		}
+		pw.CloseWithError(err)
+	}()
 
-	// Generate S3 key with timestamp
-	timestamp := time.Now().Format("2006-01-02-15-04-05")
-	s3Key := fmt.Sprintf("%s/karpenter-nodeclaims-%s.csv", strings.TrimSuffix(s3Prefix, "/"), timestamp)
+	return pr, uploader.Options{ContentEncoding: gzipCompression}
+}
 
-	// Upload to S3
-	_, err = client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s3Bucket),
-		Key:         aws.String(s3Key),
-		Body:        bytes.NewReader([]byte(csvData)),
-		ContentType: aws.String("text/csv"),
-	})
+// maybeCompress gzips data in memory when compress is set, so callers that
+// need the final byte count up front (e.g. to compute Content-MD5) can get
+// it without a pipe.
+func maybeCompress(data []byte, compress bool) ([]byte, error) {
+	if !compress {
+		return data, nil
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to upload to S3: %w", err)
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
 	}
 
-	fmt.Fprintf(os.Stderr, "Successfully uploaded to s3://%s/%s\n", s3Bucket, s3Key)
-	return nil
-}
\ No newline at end of file
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,46 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serializer
+
+import (
+	"bytes"
+	"fmt"
+
+	lp4k "github.com/awslabs/LogParserForKarpenter/parser"
+)
+
+type csvSerializer struct{}
+
+func init() {
+	Register("csv", func() Serializer { return csvSerializer{} })
+}
+
+func (csvSerializer) Serialize(nodeclaimmap *map[string]lp4k.Nodeclaimstruct) ([]byte, error) {
+	records := toRecords(nodeclaimmap)
+
+	var buf bytes.Buffer
+
+	// Generate the header from the zero-value struct's field names, so an
+	// empty map still gets a header and the file shape is stable
+	// regardless of how many nodeclaims were parsed.
+	header := fields(lp4k.Nodeclaimstruct{})
+	buf.WriteString("Nodeclaim[1]")
+	for i, f := range header {
+		fmt.Fprintf(&buf, ",%s[%d]", f.Name, i+2)
+	}
+	buf.WriteString("\n")
+
+	for _, r := range records {
+		buf.WriteString(r.Key)
+		for _, f := range r.Fields {
+			fmt.Fprintf(&buf, ",%v", f.Value)
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (csvSerializer) Ext() string         { return "csv" }
+func (csvSerializer) ContentType() string { return "text/csv" }
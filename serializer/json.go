@@ -0,0 +1,42 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serializer
+
+import (
+	"encoding/json"
+
+	lp4k "github.com/awslabs/LogParserForKarpenter/parser"
+)
+
+type jsonSerializer struct{}
+
+func init() {
+	Register("json", func() Serializer { return jsonSerializer{} })
+}
+
+// toMap turns a Record into an ordered-ish map suitable for json.Marshal;
+// Go's json package re-sorts object keys alphabetically on encode, so
+// field order in the source struct is not preserved in the output.
+func toMap(r Record) map[string]interface{} {
+	row := make(map[string]interface{}, len(r.Fields)+1)
+	row["Nodeclaim"] = r.Key
+	for _, f := range r.Fields {
+		row[f.Name] = f.Value
+	}
+	return row
+}
+
+func (jsonSerializer) Serialize(nodeclaimmap *map[string]lp4k.Nodeclaimstruct) ([]byte, error) {
+	records := toRecords(nodeclaimmap)
+
+	rows := make([]map[string]interface{}, len(records))
+	for i, r := range records {
+		rows[i] = toMap(r)
+	}
+
+	return json.Marshal(rows)
+}
+
+func (jsonSerializer) Ext() string         { return "json" }
+func (jsonSerializer) ContentType() string { return "application/json" }
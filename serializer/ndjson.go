@@ -0,0 +1,34 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+
+	lp4k "github.com/awslabs/LogParserForKarpenter/parser"
+)
+
+type ndjsonSerializer struct{}
+
+func init() {
+	Register("ndjson", func() Serializer { return ndjsonSerializer{} })
+}
+
+func (ndjsonSerializer) Serialize(nodeclaimmap *map[string]lp4k.Nodeclaimstruct) ([]byte, error) {
+	records := toRecords(nodeclaimmap)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		if err := enc.Encode(toMap(r)); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (ndjsonSerializer) Ext() string         { return "ndjson" }
+func (ndjsonSerializer) ContentType() string { return "application/x-ndjson" }
@@ -0,0 +1,91 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serializer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/writer"
+
+	lp4k "github.com/awslabs/LogParserForKarpenter/parser"
+)
+
+type parquetSerializer struct{}
+
+func init() {
+	Register("parquet", func() Serializer { return parquetSerializer{} })
+}
+
+// parquetTag maps a Go kind to the parquet-go JSON schema tag that gives it
+// a proper typed column (int64 for ints/durations, e.g.) instead of the
+// stringified columns the CSV path produces.
+func parquetTag(name string, kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=INT64"}`, name)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=INT64, convertedtype=UINT_64"}`, name)
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=DOUBLE"}`, name)
+	case reflect.Bool:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=BOOLEAN"}`, name)
+	default:
+		return fmt.Sprintf(`{"Tag":"name=%s, type=BYTE_ARRAY, convertedtype=UTF8"}`, name)
+	}
+}
+
+// schemaFor derives a parquet-go JSON schema string from lp4k.Nodeclaimstruct,
+// so the column types (timestamps, durations, ints) match the Go struct
+// instead of falling back to stringified CSV columns.
+func schemaFor() string {
+	t := reflect.TypeOf(lp4k.Nodeclaimstruct{})
+
+	// Fields must be a list of tag objects, not tag strings - parquet-go's
+	// schema.NewSchemaHandlerFromJSON unmarshals each entry straight into a
+	// JSONSchemaItemType, which fails if it's handed an escaped string
+	// instead of an object.
+	fields := make([]json.RawMessage, 0, t.NumField()+1)
+	fields = append(fields, json.RawMessage(`{"Tag":"name=Nodeclaim, type=BYTE_ARRAY, convertedtype=UTF8"}`))
+	for i := 0; i < t.NumField(); i++ {
+		fields = append(fields, json.RawMessage(parquetTag(t.Field(i).Name, t.Field(i).Type.Kind())))
+	}
+
+	schema, _ := json.Marshal(map[string]interface{}{
+		"Tag":    "name=parquet_go_root, repetitiontype=REQUIRED",
+		"Fields": fields,
+	})
+	return string(schema)
+}
+
+func (parquetSerializer) Serialize(nodeclaimmap *map[string]lp4k.Nodeclaimstruct) ([]byte, error) {
+	records := toRecords(nodeclaimmap)
+
+	file := buffer.NewBufferFileFromBytes(nil)
+	pw, err := writer.NewJSONWriter(schemaFor(), file, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+
+	for _, r := range records {
+		row, err := json.Marshal(toMap(r))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal nodeclaim %s: %w", r.Key, err)
+		}
+		if err := pw.Write(string(row)); err != nil {
+			return nil, fmt.Errorf("failed to write nodeclaim %s: %w", r.Key, err)
+		}
+	}
+
+	if err := pw.WriteStop(); err != nil {
+		return nil, fmt.Errorf("failed to finalize parquet file: %w", err)
+	}
+
+	return file.Bytes(), nil
+}
+
+func (parquetSerializer) Ext() string         { return "parquet" }
+func (parquetSerializer) ContentType() string { return "application/vnd.apache.parquet" }
@@ -0,0 +1,75 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serializer
+
+import (
+	"reflect"
+
+	lp4k "github.com/awslabs/LogParserForKarpenter/parser"
+)
+
+// Field is one column of a flattened Nodeclaimstruct.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// Record is a single nodeclaim flattened into an ordered list of fields,
+// keyed by the original map key.
+type Record struct {
+	Key    string
+	Fields []Field
+}
+
+// toRecords flattens nodeclaimmap into a stable, sorted slice of Records
+// using reflection. Every Serializer derives its rows (and, for Parquet,
+// its schema) from this one path so they can never drift from one another.
+func toRecords(nodeclaimmap *map[string]lp4k.Nodeclaimstruct) []Record {
+	type keyvalue struct {
+		key   string
+		value lp4k.Nodeclaimstruct
+	}
+
+	s := make([]keyvalue, 0, len(*nodeclaimmap))
+	for k, v := range *nodeclaimmap {
+		s = append(s, keyvalue{k, v})
+	}
+
+	// Sort by created time (simple bubble sort for consistency). Compared
+	// on the typed struct field directly, not a flattened interface{}, so
+	// this keeps working regardless of Createdtime's concrete type - and
+	// keeps the deterministic ordering that chunk0-6's content hash relies on.
+	for i := 0; i < len(s); i++ {
+		for j := i + 1; j < len(s); j++ {
+			// Ties on Createdtime fall back to the map key, since map
+			// iteration order is random.
+			if s[i].value.Createdtime > s[j].value.Createdtime ||
+				(s[i].value.Createdtime == s[j].value.Createdtime && s[i].key > s[j].key) {
+				s[i], s[j] = s[j], s[i]
+			}
+		}
+	}
+
+	records := make([]Record, len(s))
+	for i, kv := range s {
+		records[i] = Record{Key: kv.key, Fields: fields(kv.value)}
+	}
+
+	return records
+}
+
+func fields(nodeclaimstruct lp4k.Nodeclaimstruct) []Field {
+	reflecttype := reflect.TypeOf(nodeclaimstruct)
+	reflectval := reflect.ValueOf(nodeclaimstruct)
+
+	out := make([]Field, reflecttype.NumField())
+	for i := range out {
+		out[i] = Field{
+			Name:  reflecttype.Field(i).Name,
+			Value: reflectval.Field(i).Interface(),
+		}
+	}
+
+	return out
+}
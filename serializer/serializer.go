@@ -0,0 +1,55 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package serializer turns a nodeclaim map into bytes ready to be uploaded,
+// in whichever output format the user asked for via LP4K_OUTPUT_FORMAT.
+package serializer
+
+import (
+	"fmt"
+	"os"
+
+	lp4k "github.com/awslabs/LogParserForKarpenter/parser"
+)
+
+// Serializer encodes a nodeclaim map into a byte payload, and knows the
+// file extension and content type that payload should be uploaded with.
+type Serializer interface {
+	Serialize(nodeclaimmap *map[string]lp4k.Nodeclaimstruct) ([]byte, error)
+	Ext() string
+	ContentType() string
+}
+
+// Factory constructs a Serializer.
+type Factory func() Serializer
+
+var registry = map[string]Factory{}
+
+// Register associates a format name (as used in LP4K_OUTPUT_FORMAT) with a
+// Factory. Each format's own file calls this from init().
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+const (
+	// outputFormatEnv selects the output format.
+	outputFormatEnv = "LP4K_OUTPUT_FORMAT"
+
+	// defaultFormat preserves the historical CSV-only behavior.
+	defaultFormat = "csv"
+)
+
+// New looks up LP4K_OUTPUT_FORMAT and constructs the selected Serializer.
+func New() (Serializer, error) {
+	name := os.Getenv(outputFormatEnv)
+	if name == "" {
+		name = defaultFormat
+	}
+
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("serializer: unknown format %q (set %s to one of csv, json, ndjson, parquet)", name, outputFormatEnv)
+	}
+
+	return factory(), nil
+}
@@ -0,0 +1,113 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serializer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+
+	lp4k "github.com/awslabs/LogParserForKarpenter/parser"
+)
+
+func testNodeclaimmap() *map[string]lp4k.Nodeclaimstruct {
+	return &map[string]lp4k.Nodeclaimstruct{
+		"i-111": {Createdtime: 200},
+		"i-222": {Createdtime: 100},
+	}
+}
+
+func TestCSVSerializeRoundTrip(t *testing.T) {
+	data, err := csvSerializer{}.Serialize(testNodeclaimmap())
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3 (header + 2 rows): %q", len(lines), data)
+	}
+
+	// Createdtime 100 (i-222) should sort before Createdtime 200 (i-111).
+	if !strings.HasPrefix(lines[1], "i-222,") {
+		t.Errorf("first row = %q, want it to start with i-222 (lower Createdtime)", lines[1])
+	}
+}
+
+func TestJSONSerializeRoundTrip(t *testing.T) {
+	data, err := jsonSerializer{}.Serialize(testNodeclaimmap())
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[0]["Nodeclaim"] != "i-222" {
+		t.Errorf("rows[0][Nodeclaim] = %v, want i-222 (lower Createdtime)", rows[0]["Nodeclaim"])
+	}
+}
+
+func TestNDJSONSerializeRoundTrip(t *testing.T) {
+	data, err := ndjsonSerializer{}.Serialize(testNodeclaimmap())
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var rows []map[string]interface{}
+	for scanner.Scan() {
+		var row map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("Unmarshal line %q: %v", scanner.Text(), err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d lines, want 2", len(rows))
+	}
+}
+
+func TestParquetSerializeRoundTrip(t *testing.T) {
+	data, err := parquetSerializer{}.Serialize(testNodeclaimmap())
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	file := buffer.NewBufferFileFromBytes(data)
+	pr, err := reader.NewParquetReader(file, nil, 1)
+	if err != nil {
+		t.Fatalf("NewParquetReader: %v", err)
+	}
+	defer pr.ReadStop()
+
+	if got, want := pr.GetNumRows(), int64(2); got != want {
+		t.Fatalf("GetNumRows() = %d, want %d", got, want)
+	}
+
+	rows, err := pr.ReadByNumber(2)
+	if err != nil {
+		t.Fatalf("ReadByNumber: %v", err)
+	}
+
+	got := make([]string, len(rows))
+	for i, row := range rows {
+		got[i] = reflect.ValueOf(row).FieldByName("Nodeclaim").String()
+	}
+
+	// Createdtime 100 (i-222) should sort before Createdtime 200 (i-111).
+	if got[0] != "i-222" {
+		t.Errorf("rows[0].Nodeclaim = %q, want i-222 (lower Createdtime)", got[0])
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const fileRootEnv = "LP4K_FILE_ROOT"
+
+// fileUploader writes objects to a local directory, for air-gapped
+// clusters with no network path to an object store.
+type fileUploader struct {
+	root string
+}
+
+func init() {
+	Register("file", newFileUploader)
+}
+
+func newFileUploader() (Uploader, error) {
+	root := os.Getenv(fileRootEnv)
+	if root == "" {
+		root = "."
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("uploader: unable to create %s: %w", fileRootEnv, err)
+	}
+
+	return &fileUploader{root: root}, nil
+}
+
+// Upload writes body to <root>/key, creating any intermediate directories
+// the key implies.
+func (u *fileUploader) Upload(_ context.Context, key string, body io.Reader, _ string, _ Options) error {
+	path := filepath.Join(u.root, filepath.FromSlash(key))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, body); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Exists reports whether key is already present under root, so dedupe
+// mode can skip re-writing unchanged content.
+func (u *fileUploader) Exists(_ context.Context, key string) (bool, error) {
+	_, err := os.Stat(filepath.Join(u.root, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
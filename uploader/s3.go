@@ -0,0 +1,254 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package uploader
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	s3BucketEnv      = "LP4K_S3_BUCKET"
+	s3RegionEnv      = "LP4K_S3_REGION"
+	s3EndpointEnv    = "LP4K_S3_ENDPOINT"
+	s3AccessKeyEnv   = "LP4K_S3_ACCESS_KEY"
+	s3SecretKeyEnv   = "LP4K_S3_SECRET_KEY"
+	s3PathStyleEnv   = "LP4K_S3_PATH_STYLE"
+	s3PartSizeEnv    = "LP4K_S3_PART_SIZE_MB"
+	s3ConcurrencyEnv = "LP4K_S3_CONCURRENCY"
+
+	s3SSEEnv            = "LP4K_S3_SSE"
+	s3KMSKeyIDEnv       = "LP4K_S3_KMS_KEY_ID"
+	s3SSECustomerKeyEnv = "LP4K_S3_SSE_CUSTOMER_KEY"
+	s3ACLEnv            = "LP4K_S3_ACL"
+	s3StorageClassEnv   = "LP4K_S3_STORAGE_CLASS"
+	s3TagsEnv           = "LP4K_S3_TAGS"
+
+	defaultPartSizeMB  = 5
+	defaultConcurrency = 5
+
+	// maxSinglePartBytes is S3's hard limit on a single PutObject call. Above
+	// this, an object must go through multipart upload regardless of
+	// Content-MD5, since there's no whole-object integrity header multipart
+	// can honor.
+	maxSinglePartBytes = 5 * 1024 * 1024 * 1024
+)
+
+// s3Uploader uploads objects to any S3-compatible store. Pointing it at a
+// non-AWS endpoint (MinIO, Ceph, Cloudflare R2, GCS's S3 interop API, ...)
+// is just a matter of setting LP4K_S3_ENDPOINT.
+type s3Uploader struct {
+	bucket      string
+	client      *s3.Client
+	partSizeMB  int
+	concurrency int
+
+	// Server-side encryption, ACL, storage class, and tagging, applied to
+	// every object this uploader writes. Regulated accounts commonly
+	// require all of these for compliance and cost allocation.
+	sse            types.ServerSideEncryption
+	kmsKeyID       string
+	sseCustomerKey string
+	acl            types.ObjectCannedACL
+	storageClass   types.StorageClass
+	tags           string
+}
+
+func init() {
+	Register("s3", newS3Uploader)
+	// MinIO and GCS are S3-compatible; they reuse the same driver and only
+	// differ in the environment variables a user typically sets.
+	Register("minio", newS3Uploader)
+	Register("gcs", newS3Uploader)
+}
+
+func newS3Uploader() (Uploader, error) {
+	bucket := os.Getenv(s3BucketEnv)
+	if bucket == "" {
+		return nil, fmt.Errorf("uploader: %s must be set", s3BucketEnv)
+	}
+
+	region := os.Getenv(s3RegionEnv)
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	ctx := context.Background()
+	var optFns []func(*config.LoadOptions) error
+	optFns = append(optFns, config.WithRegion(region))
+
+	if accessKey := os.Getenv(s3AccessKeyEnv); accessKey != "" {
+		optFns = append(optFns, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKey, os.Getenv(s3SecretKeyEnv), ""),
+		))
+	}
+
+	// Retry transient failures (including individual multipart upload
+	// parts) with exponential backoff rather than failing the whole run.
+	optFns = append(optFns, config.WithRetryer(func() aws.Retryer {
+		return retry.NewStandard(func(o *retry.StandardOptions) {
+			o.MaxAttempts = 5
+			o.Backoff = retry.NewExponentialJitterBackoff(30 * time.Second)
+		})
+	}))
+
+	cfg, err := config.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS SDK config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := os.Getenv(s3EndpointEnv); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		if os.Getenv(s3PathStyleEnv) == "true" {
+			o.UsePathStyle = true
+		}
+	})
+
+	return &s3Uploader{
+		bucket:         bucket,
+		client:         client,
+		partSizeMB:     intEnvOrDefault(s3PartSizeEnv, defaultPartSizeMB),
+		concurrency:    intEnvOrDefault(s3ConcurrencyEnv, defaultConcurrency),
+		sse:            types.ServerSideEncryption(os.Getenv(s3SSEEnv)),
+		kmsKeyID:       os.Getenv(s3KMSKeyIDEnv),
+		sseCustomerKey: os.Getenv(s3SSECustomerKeyEnv),
+		acl:            types.ObjectCannedACL(os.Getenv(s3ACLEnv)),
+		storageClass:   types.StorageClass(os.Getenv(s3StorageClassEnv)),
+		tags:           os.Getenv(s3TagsEnv),
+	}, nil
+}
+
+func intEnvOrDefault(env string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(env))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
+
+// Upload sends body to S3. When opts.ContentMD5 is set and body fits in a
+// single PutObject call, it's sent that way, since S3 only honors a
+// whole-object Content-MD5 on a single-part upload and silently ignores it
+// once a payload is split across multipart parts. Otherwise it streams via a
+// multipart upload so memory use stays bounded regardless of how large the
+// serialized payload is (dropping the Content-MD5 check, since S3 has no
+// multipart equivalent); part size and concurrency are tunable via
+// LP4K_S3_PART_SIZE_MB/LP4K_S3_CONCURRENCY.
+func (u *s3Uploader) Upload(ctx context.Context, key string, body io.Reader, contentType string, opts Options) error {
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	if opts.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(opts.ContentEncoding)
+	}
+	if opts.ContentSHA256 != "" {
+		input.Metadata = map[string]string{"content-sha256": opts.ContentSHA256}
+	}
+	u.applyObjectOptions(input)
+
+	if opts.ContentMD5 != "" {
+		if sizer, ok := body.(interface{ Len() int }); ok && sizer.Len() <= maxSinglePartBytes {
+			input.ContentMD5 = aws.String(opts.ContentMD5)
+			if _, err := u.client.PutObject(ctx, input); err != nil {
+				return fmt.Errorf("failed to upload to s3://%s/%s: %w", u.bucket, key, err)
+			}
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Content-MD5 integrity check skipped for s3://%s/%s: payload too large for a single PutObject\n", u.bucket, key)
+	}
+
+	uploader := manager.NewUploader(u.client, func(mu *manager.Uploader) {
+		mu.PartSize = int64(u.partSizeMB) * 1024 * 1024
+		mu.Concurrency = u.concurrency
+	})
+
+	if _, err := uploader.Upload(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload to s3://%s/%s: %w", u.bucket, key, err)
+	}
+
+	return nil
+}
+
+// Exists reports whether key is already present in the bucket, via
+// HeadObject, so dedupe mode can skip re-uploading unchanged content. SSE-C
+// customer key headers are required on HeadObject for the same object,
+// otherwise S3 rejects the request before it can report NotFound.
+func (u *s3Uploader) Exists(ctx context.Context, key string) (bool, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	}
+	if u.sseCustomerKey != "" {
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = u.sseCustomerHeaders()
+	}
+
+	_, err := u.client.HeadObject(ctx, input)
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head s3://%s/%s: %w", u.bucket, key, err)
+	}
+
+	return true, nil
+}
+
+// sseCustomerHeaders derives the SSE-C algorithm, key, and key-MD5 headers
+// from sseCustomerKey, for use on both PutObject and HeadObject.
+func (u *s3Uploader) sseCustomerHeaders() (algorithm, key, keyMD5 *string) {
+	sum := md5.Sum([]byte(u.sseCustomerKey))
+	return aws.String("AES256"),
+		aws.String(base64.StdEncoding.EncodeToString([]byte(u.sseCustomerKey))),
+		aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// applyObjectOptions sets SSE, ACL, storage class, and tagging on input
+// from whichever of LP4K_S3_SSE/LP4K_S3_KMS_KEY_ID/LP4K_S3_SSE_CUSTOMER_KEY/
+// LP4K_S3_ACL/LP4K_S3_STORAGE_CLASS/LP4K_S3_TAGS were set.
+func (u *s3Uploader) applyObjectOptions(input *s3.PutObjectInput) {
+	switch u.sse {
+	case types.ServerSideEncryptionAwsKms:
+		input.ServerSideEncryption = u.sse
+		if u.kmsKeyID != "" {
+			input.SSEKMSKeyId = aws.String(u.kmsKeyID)
+		}
+	case types.ServerSideEncryptionAes256:
+		input.ServerSideEncryption = u.sse
+	}
+
+	if u.sseCustomerKey != "" {
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = u.sseCustomerHeaders()
+	}
+
+	if u.acl != "" {
+		input.ACL = u.acl
+	}
+	if u.storageClass != "" {
+		input.StorageClass = u.storageClass
+	}
+	if u.tags != "" {
+		input.Tagging = aws.String(u.tags)
+	}
+}
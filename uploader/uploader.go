@@ -0,0 +1,79 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package uploader defines a pluggable object-storage backend abstraction
+// so the log parser can ship its output to S3-compatible stores, a local
+// filesystem, or WebDAV without the caller knowing which one is in use.
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Options carries per-upload knobs that apply across backends, beyond the
+// fixed Upload parameters.
+type Options struct {
+	// ContentEncoding is set on the uploaded object when the payload has
+	// been compressed (e.g. "gzip"), so clients decompress it on fetch.
+	ContentEncoding string
+
+	// ContentMD5 is the base64-encoded MD5 of body, so the backend can
+	// ask the store to verify integrity server-side. Only set when the
+	// full payload has already been buffered (e.g. dedupe mode).
+	ContentMD5 string
+
+	// ContentSHA256 is the hex-encoded SHA-256 of the unencoded payload.
+	// Backends that support user metadata should store it so re-runs can
+	// detect whether an existing object's content actually changed.
+	ContentSHA256 string
+}
+
+// Uploader is implemented by every object-storage backend.
+type Uploader interface {
+	// Upload writes body to the backend under key with the given content type.
+	Upload(ctx context.Context, key string, body io.Reader, contentType string, opts Options) error
+}
+
+// Exister is implemented by backends that can cheaply check whether an
+// object already exists, so idempotent re-runs can skip uploading it again.
+type Exister interface {
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// Factory constructs an Uploader from the process environment.
+type Factory func() (Uploader, error)
+
+var registry = map[string]Factory{}
+
+// Register associates a backend name (as used in LP4K_UPLOADER) with a
+// Factory. Drivers call this from their own init().
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+const (
+	// uploaderEnv selects which registered backend to use.
+	uploaderEnv = "LP4K_UPLOADER"
+
+	// defaultBackend is used when LP4K_UPLOADER is unset, preserving the
+	// historical S3-only behavior.
+	defaultBackend = "s3"
+)
+
+// New looks up LP4K_UPLOADER and constructs the selected backend.
+func New() (Uploader, error) {
+	name := os.Getenv(uploaderEnv)
+	if name == "" {
+		name = defaultBackend
+	}
+
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("uploader: unknown backend %q (set %s to one of s3, minio, gcs, webdav, file)", name, uploaderEnv)
+	}
+
+	return factory()
+}
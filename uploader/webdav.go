@@ -0,0 +1,154 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	webdavURLEnv      = "LP4K_WEBDAV_URL"
+	webdavUsernameEnv = "LP4K_WEBDAV_USERNAME"
+	webdavPasswordEnv = "LP4K_WEBDAV_PASSWORD"
+)
+
+// webdavUploader uploads objects to a WebDAV server via HTTP PUT.
+type webdavUploader struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+
+	// knownDirs caches collection paths mkcolAll has already created, so a
+	// long-running process doesn't re-issue MKCOL for the same directories
+	// on every upload.
+	knownDirs sync.Map
+}
+
+func init() {
+	Register("webdav", newWebdavUploader)
+}
+
+func newWebdavUploader() (Uploader, error) {
+	baseURL := os.Getenv(webdavURLEnv)
+	if baseURL == "" {
+		return nil, fmt.Errorf("uploader: %s must be set", webdavURLEnv)
+	}
+
+	return &webdavUploader{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		username: os.Getenv(webdavUsernameEnv),
+		password: os.Getenv(webdavPasswordEnv),
+		client:   http.DefaultClient,
+	}, nil
+}
+
+func (u *webdavUploader) Upload(ctx context.Context, key string, body io.Reader, contentType string, opts Options) error {
+	if err := u.mkcolAll(ctx, key); err != nil {
+		// body may be the read end of an in-flight io.Pipe (e.g. gzip
+		// compression streamed in from s3.bodyFor); draining it here
+		// unblocks and frees the writer goroutine instead of leaking it.
+		io.Copy(io.Discard, body)
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.baseURL+"/"+strings.TrimPrefix(key, "/"), body)
+	if err != nil {
+		return fmt.Errorf("failed to build WebDAV request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if opts.ContentEncoding != "" {
+		req.Header.Set("Content-Encoding", opts.ContentEncoding)
+	}
+	if u.username != "" {
+		req.SetBasicAuth(u.username, u.password)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to PUT %s to WebDAV: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("WebDAV PUT %s returned status %s", key, resp.Status)
+	}
+
+	return nil
+}
+
+// mkcolAll creates every intermediate collection in key's path via MKCOL, so
+// a PUT against a deeply-nested key (the default LP4K_S3_KEY_TEMPLATE nests
+// cluster=.../year=.../month=.../day=...) doesn't 409 against a WebDAV
+// server that won't create missing parent collections itself.
+func (u *webdavUploader) mkcolAll(ctx context.Context, key string) error {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+
+	path := ""
+	for _, segment := range segments[:len(segments)-1] {
+		path += segment + "/"
+
+		if _, ok := u.knownDirs.Load(path); ok {
+			continue
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "MKCOL", u.baseURL+"/"+path, nil)
+		if err != nil {
+			return fmt.Errorf("failed to build WebDAV MKCOL request: %w", err)
+		}
+		if u.username != "" {
+			req.SetBasicAuth(u.username, u.password)
+		}
+
+		resp, err := u.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to MKCOL %s on WebDAV: %w", path, err)
+		}
+		resp.Body.Close()
+
+		// 201 Created and 405 Method Not Allowed (collection already
+		// exists) both mean the collection is there; anything else means
+		// the PUT below would fail too.
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("WebDAV MKCOL %s returned status %s", path, resp.Status)
+		}
+
+		u.knownDirs.Store(path, struct{}{})
+	}
+
+	return nil
+}
+
+// Exists reports whether key is already present on the WebDAV server, so
+// dedupe mode can skip re-uploading unchanged content.
+func (u *webdavUploader) Exists(ctx context.Context, key string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.baseURL+"/"+strings.TrimPrefix(key, "/"), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to build WebDAV request: %w", err)
+	}
+	if u.username != "" {
+		req.SetBasicAuth(u.username, u.password)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to HEAD %s on WebDAV: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("WebDAV HEAD %s returned status %s", key, resp.Status)
+	}
+}